@@ -0,0 +1,90 @@
+package pyprclient
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// maxFrameSize bounds how large a length-prefixed frame we're willing to
+// believe. It also lets us tell a real frame length apart from the first
+// four bytes of a legacy plain-text reply, which is never this large.
+const maxFrameSize = 16 * 1024 * 1024
+
+// Request is one command in the length-prefixed JSON protocol spoken over
+// .pyprland.sock. A lone Request is sent as a single object; several are
+// sent together as a JSON array for batching.
+type Request struct {
+	Cmd  string   `json:"cmd"`
+	Args []string `json:"args"`
+	ID   int      `json:"id"`
+}
+
+// Response is the daemon's reply to one Request.
+type Response struct {
+	ID     int             `json:"id"`
+	OK     bool            `json:"ok"`
+	Result json.RawMessage `json:"result"`
+	Error  *string         `json:"error"`
+}
+
+// writeFrame writes payload prefixed with its length as a big-endian
+// uint32, per the framed .pyprland.sock protocol.
+func writeFrame(w io.Writer, payload []byte) error {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readResponseFrame reads one length-prefixed frame from r. Daemons
+// predating the framed protocol instead just write a plain-text reply and
+// close the connection, so a frame whose length looks implausible (or is
+// truncated) is handed back as legacy text rather than an error, letting
+// callers fall back for one release. A nil frame and nil legacy reply both
+// nil means the daemon closed the connection without replying.
+func readResponseFrame(r io.Reader) (frame []byte, legacy []byte, err error) {
+	header := make([]byte, 4)
+	n, err := io.ReadFull(r, header)
+	if err != nil {
+		if n == 0 && errors.Is(err, io.EOF) {
+			return nil, nil, nil
+		}
+		return nil, header[:n], nil
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	if length > maxFrameSize {
+		rest, _ := io.ReadAll(r)
+		return nil, append(header, rest...), nil
+	}
+
+	body := make([]byte, length)
+	if n, err := io.ReadFull(r, body); err != nil {
+		return nil, append(header, body[:n]...), nil
+	}
+	return body, nil, nil
+}
+
+// resultString renders a Response's Result as a string: unquoted if it's a
+// JSON string, or its raw JSON text otherwise.
+func resultString(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// ResultString renders r's Result as a string, unquoted if it's a JSON
+// string. Useful for callers driving Batch directly, e.g. pypr-client.
+func (r Response) ResultString() string {
+	return resultString(r.Result)
+}
@@ -0,0 +1,80 @@
+package pyprclient
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadResponseFrameFramed(t *testing.T) {
+	payload := []byte(`{"id":1,"ok":true,"result":"done","error":null}`)
+	var buf bytes.Buffer
+	if err := writeFrame(&buf, payload); err != nil {
+		t.Fatalf("writeFrame: %v", err)
+	}
+
+	frame, legacy, err := readResponseFrame(&buf)
+	if err != nil {
+		t.Fatalf("readResponseFrame: %v", err)
+	}
+	if legacy != nil {
+		t.Fatalf("legacy = %q, want nil", legacy)
+	}
+	if string(frame) != string(payload) {
+		t.Fatalf("frame = %q, want %q", frame, payload)
+	}
+}
+
+func TestReadResponseFrameLegacyText(t *testing.T) {
+	frame, legacy, err := readResponseFrame(strings.NewReader("pong"))
+	if err != nil {
+		t.Fatalf("readResponseFrame: %v", err)
+	}
+	if frame != nil {
+		t.Fatalf("frame = %q, want nil", frame)
+	}
+	if string(legacy) != "pong" {
+		t.Fatalf("legacy = %q, want %q", legacy, "pong")
+	}
+}
+
+func TestReadResponseFrameShortLegacyText(t *testing.T) {
+	// Shorter than the 4-byte length prefix: still a legacy reply, not an
+	// error.
+	frame, legacy, err := readResponseFrame(strings.NewReader("ok"))
+	if err != nil {
+		t.Fatalf("readResponseFrame: %v", err)
+	}
+	if frame != nil {
+		t.Fatalf("frame = %q, want nil", frame)
+	}
+	if string(legacy) != "ok" {
+		t.Fatalf("legacy = %q, want %q", legacy, "ok")
+	}
+}
+
+func TestReadResponseFrameNoReply(t *testing.T) {
+	frame, legacy, err := readResponseFrame(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("readResponseFrame: %v", err)
+	}
+	if frame != nil || legacy != nil {
+		t.Fatalf("frame = %q, legacy = %q, want nil, nil", frame, legacy)
+	}
+}
+
+func TestResultString(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+	}{
+		{`"hello"`, "hello"},
+		{`42`, "42"},
+		{``, ""},
+	}
+	for _, tc := range cases {
+		if got := resultString([]byte(tc.raw)); got != tc.want {
+			t.Errorf("resultString(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+// Package pyprclient is a typed Go client for the pyprland daemon's Unix
+// sockets. It is the library pypr-client is built on, so third-party bars
+// and launchers can talk to pyprland without shelling out to the binary or
+// hand-joining os.Args into a command string.
+package pyprclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds how long Command waits for the daemon to reply
+// when the Client was built with New.
+const DefaultTimeout = 5 * time.Second
+
+// Client talks to a running pyprland daemon over its Unix sockets.
+type Client struct {
+	socketPath  string
+	eventSocket string
+	timeout     time.Duration
+}
+
+// New resolves the daemon's sockets from the environment
+// (XDG_RUNTIME_DIR, HYPRLAND_INSTANCE_SIGNATURE). It does not dial
+// anything; each call opens its own connection.
+func New() (*Client, error) {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if runtimeDir == "" || signature == "" {
+		return nil, fmt.Errorf("pyprclient: XDG_RUNTIME_DIR or HYPRLAND_INSTANCE_SIGNATURE not set")
+	}
+	base := fmt.Sprintf("%s/hypr/%s", runtimeDir, signature)
+	return &Client{
+		socketPath:  base + "/.pyprland.sock",
+		eventSocket: base + "/.socket2.sock",
+		timeout:     DefaultTimeout,
+	}, nil
+}
+
+// WithTimeout returns a copy of c whose Command calls wait at most d for a
+// daemon reply.
+func (c *Client) WithTimeout(d time.Duration) *Client {
+	clone := *c
+	clone.timeout = d
+	return &clone
+}
+
+// dial opens .pyprland.sock and arranges for it to be closed if ctx is
+// canceled before the caller is done with it. The caller must still close
+// the connection itself once finished.
+func (c *Client) dial(ctx context.Context) (net.Conn, func(), error) {
+	conn, err := net.Dial("unix", c.socketPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pyprclient: connecting to %s: %w", c.socketPath, err)
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	return conn, func() { close(done) }, nil
+}
+
+// errUnframedReply signals that a daemon didn't reply with a valid framed
+// response, so Command should retry over the legacy plain-text protocol.
+var errUnframedReply = errors.New("pyprclient: reply was not framed")
+
+// Command sends a single request to .pyprland.sock using the length-prefixed
+// JSON protocol and returns the daemon's result. If the daemon doesn't
+// reply in kind - because it's still running the legacy plain-text
+// protocol - Command retries over a fresh connection using the plain-text
+// form that daemon actually understands, so a client upgraded ahead of the
+// daemon keeps working for one release.
+func (c *Client) Command(ctx context.Context, name string, args ...string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("pyprclient: command name must not be empty")
+	}
+
+	result, err := c.commandFramed(ctx, name, args...)
+	if errors.Is(err, errUnframedReply) {
+		return c.commandLegacy(ctx, name, args...)
+	}
+	return result, err
+}
+
+// commandFramed speaks the length-prefixed JSON protocol. It returns
+// errUnframedReply, rather than a hard error, when the reply doesn't look
+// framed at all - that's the signal the daemon predates this protocol.
+func (c *Client) commandFramed(ctx context.Context, name string, args ...string) (string, error) {
+	conn, stopWatch, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	defer stopWatch()
+
+	payload, err := json.Marshal(Request{Cmd: name, Args: args, ID: 1})
+	if err != nil {
+		return "", fmt.Errorf("pyprclient: encoding request: %w", err)
+	}
+	if err := writeFrame(conn, payload); err != nil {
+		return "", fmt.Errorf("pyprclient: writing request: %w", err)
+	}
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		if err := cw.CloseWrite(); err != nil {
+			return "", fmt.Errorf("pyprclient: closing write side: %w", err)
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(c.timeout))
+	frame, _, err := readResponseFrame(conn)
+	if err != nil {
+		return "", fmt.Errorf("pyprclient: reading reply: %w", err)
+	}
+	if frame == nil {
+		return "", errUnframedReply
+	}
+
+	var resp Response
+	if err := json.Unmarshal(frame, &resp); err != nil {
+		return "", fmt.Errorf("pyprclient: decoding reply frame: %w", err)
+	}
+	if !resp.OK {
+		msg := "unknown error"
+		if resp.Error != nil {
+			msg = *resp.Error
+		}
+		return "", fmt.Errorf("pyprclient: daemon error: %s", msg)
+	}
+	return resultString(resp.Result), nil
+}
+
+// commandLegacy speaks the pre-framing protocol: a bare space-joined
+// command string, with the daemon's whole reply read back as plain text.
+func (c *Client) commandLegacy(ctx context.Context, name string, args ...string) (string, error) {
+	conn, stopWatch, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	defer stopWatch()
+
+	message := strings.Join(append([]string{name}, args...), " ")
+	if _, err := conn.Write([]byte(message)); err != nil {
+		return "", fmt.Errorf("pyprclient: writing legacy request: %w", err)
+	}
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		if err := cw.CloseWrite(); err != nil {
+			return "", fmt.Errorf("pyprclient: closing write side: %w", err)
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(c.timeout))
+	reply, err := readAllText(conn)
+	if err != nil {
+		return "", fmt.Errorf("pyprclient: reading legacy reply: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(reply)
+	if strings.HasPrefix(strings.ToLower(trimmed), "error") {
+		return "", fmt.Errorf("pyprclient: daemon error: %s", trimmed)
+	}
+	return trimmed, nil
+}
+
+// readAllText reads conn until EOF and returns everything read as a string.
+func readAllText(conn net.Conn) (string, error) {
+	var out strings.Builder
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			out.Write(buf[:n])
+		}
+		if err != nil {
+			if err == io.EOF {
+				return out.String(), nil
+			}
+			return out.String(), err
+		}
+	}
+}
+
+// Batch sends several requests as a single pipelined frame - a JSON array
+// instead of one object - and returns one Response per request, in the same
+// order. Requests with ID 0 are assigned their 1-based position in reqs.
+// Batch requires a daemon that understands the framed protocol; it returns
+// an error against a legacy plain-text daemon.
+func (c *Client) Batch(ctx context.Context, reqs []Request) ([]Response, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	for i := range reqs {
+		if reqs[i].ID == 0 {
+			reqs[i].ID = i + 1
+		}
+	}
+
+	conn, stopWatch, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	defer stopWatch()
+
+	payload, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, fmt.Errorf("pyprclient: encoding batch request: %w", err)
+	}
+	if err := writeFrame(conn, payload); err != nil {
+		return nil, fmt.Errorf("pyprclient: writing batch request: %w", err)
+	}
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		if err := cw.CloseWrite(); err != nil {
+			return nil, fmt.Errorf("pyprclient: closing write side: %w", err)
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(c.timeout))
+	frame, _, err := readResponseFrame(conn)
+	if err != nil {
+		return nil, fmt.Errorf("pyprclient: reading batch reply: %w", err)
+	}
+	if frame == nil {
+		return nil, fmt.Errorf("pyprclient: daemon does not support batched requests")
+	}
+
+	var resps []Response
+	if err := json.Unmarshal(frame, &resps); err != nil {
+		return nil, fmt.Errorf("pyprclient: decoding batch reply: %w", err)
+	}
+	return resps, nil
+}
+
+// Toggle toggles visibility of the scratchpad named name. [scratchpads]
+func (c *Client) Toggle(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("pyprclient: scratchpad name must not be empty")
+	}
+	_, err := c.Command(ctx, "toggle", name)
+	return err
+}
+
+// Show shows the scratchpad named name. [scratchpads]
+func (c *Client) Show(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("pyprclient: scratchpad name must not be empty")
+	}
+	_, err := c.Command(ctx, "show", name)
+	return err
+}
+
+// Hide hides the scratchpad named name. [scratchpads]
+func (c *Client) Hide(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("pyprclient: scratchpad name must not be empty")
+	}
+	_, err := c.Command(ctx, "hide", name)
+	return err
+}
+
+// Zoom zooms to factor, or toggles the zoom level if factor is 0. [magnify]
+func (c *Client) Zoom(ctx context.Context, factor float64) error {
+	if factor == 0 {
+		_, err := c.Command(ctx, "zoom")
+		return err
+	}
+	if factor < 0 {
+		return fmt.Errorf("pyprclient: zoom factor must be positive, got %v", factor)
+	}
+	_, err := c.Command(ctx, "zoom", fmt.Sprintf("%g", factor))
+	return err
+}
+
+// ShiftMonitors swaps monitors' workspaces in the given direction, which
+// must be +1 or -1. [shift_monitors]
+func (c *Client) ShiftMonitors(ctx context.Context, delta int) error {
+	if delta != 1 && delta != -1 {
+		return fmt.Errorf("pyprclient: shift_monitors delta must be +1 or -1, got %d", delta)
+	}
+	_, err := c.Command(ctx, "shift_monitors", fmt.Sprintf("%+d", delta))
+	return err
+}
+
+// Reload loads the configuration; new plugins are added and the config is
+// updated. [pyprland]
+func (c *Client) Reload(ctx context.Context) error {
+	_, err := c.Command(ctx, "reload")
+	return err
+}
+
+// Config is the daemon's configuration as reported by dumpjson. Its shape
+// follows pyprland's own config file, so it's kept loosely typed rather
+// than mirrored field-by-field here.
+type Config map[string]any
+
+// DumpJSON dumps the daemon's configuration in JSON format. [dumpjson]
+func (c *Client) DumpJSON(ctx context.Context) (Config, error) {
+	reply, err := c.Command(ctx, "dumpjson")
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal([]byte(reply), &cfg); err != nil {
+		return nil, fmt.Errorf("pyprclient: decoding dumpjson reply: %w", err)
+	}
+	return cfg, nil
+}
+
+// CommandInfo describes one command currently registered with the daemon,
+// as reported by "dumpjson --commands".
+type CommandInfo struct {
+	Name   string `json:"name"`
+	Plugin string `json:"plugin"`
+	Arity  int    `json:"arity"`
+	Doc    string `json:"doc"`
+}
+
+// ErrCommandRegistryUnsupported is returned by Commands when the daemon's
+// reply to "dumpjson --commands" doesn't look like a command registry -
+// most likely because the daemon predates that addition and replied with
+// its regular, unfiltered config dump instead. A daemon that rejects the
+// flag with an error reply yields that daemon error, not this one.
+var ErrCommandRegistryUnsupported = errors.New("pyprclient: daemon does not support the live command registry (dumpjson --commands)")
+
+// Commands asks the daemon for its live command registry: every command
+// currently loaded, which plugin owns it, how many arguments it takes, and
+// a one-line doc string. It drives shell-completion generation for command
+// names, so enabling a plugin or reloading the config updates which names
+// complete without touching this binary; completing argument values
+// (scratchpad names, wallpaper actions, menu entries) isn't covered by
+// this registry. It returns ErrCommandRegistryUnsupported rather than
+// guessing if the reply doesn't match that shape.
+func (c *Client) Commands(ctx context.Context) ([]CommandInfo, error) {
+	reply, err := c.Command(ctx, "dumpjson", "--commands")
+	if err != nil {
+		return nil, err
+	}
+	var cmds []CommandInfo
+	if err := json.Unmarshal([]byte(reply), &cmds); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCommandRegistryUnsupported, err)
+	}
+	for _, cmd := range cmds {
+		if cmd.Name == "" {
+			return nil, ErrCommandRegistryUnsupported
+		}
+	}
+	return cmds, nil
+}
+
+// EventKind names one of Hyprland's IPC events, e.g. "workspace" or
+// "activewindow".
+type EventKind string
+
+// A non-exhaustive set of event names Hyprland is known to emit on
+// .socket2.sock. Subscribe accepts any EventKind, not just these.
+const (
+	EventWorkspace      EventKind = "workspace"
+	EventFocusedMon     EventKind = "focusedmon"
+	EventActiveWindow   EventKind = "activewindow"
+	EventMonitorAdded   EventKind = "monitoradded"
+	EventMonitorRemoved EventKind = "monitorremoved"
+	EventFullscreen     EventKind = "fullscreen"
+)
+
+// Event is a single line off Hyprland's event socket, normalized.
+type Event struct {
+	Kind EventKind
+	Data []string
+	TS   time.Time
+}
+
+// ErrPluginEventsUnsupported is returned by SubscribePlugin: multiplexing
+// pyprland's own plugin events over .pyprland.sock requires a matching
+// daemon-side "subscribe" command that doesn't exist yet in this tree.
+var ErrPluginEventsUnsupported = errors.New("pyprclient: daemon-side plugin event subscription (.pyprland.sock \"subscribe\") is not implemented yet")
+
+// SubscribePlugin is a placeholder for subscribing to pyprland's own plugin
+// events (scratchpad shown/hidden, wallpaper changed, layout_center
+// toggled, expose entered/left) multiplexed over .pyprland.sock. That
+// requires daemon-side support this tree doesn't have, so it always fails
+// with ErrPluginEventsUnsupported. Use Subscribe for Hyprland-native
+// compositor events in the meantime.
+func (c *Client) SubscribePlugin(ctx context.Context) (<-chan Event, error) {
+	return nil, ErrPluginEventsUnsupported
+}
+
+// Subscribe opens .socket2.sock and streams Hyprland's own compositor
+// events (workspace, activewindow, monitoradded, ...) on the returned
+// channel, optionally filtered to kinds. It does not see pyprland's plugin
+// events (scratchpad shown/hidden, wallpaper changed, ...) - those are
+// multiplexed over .pyprland.sock by the daemon, which SubscribePlugin
+// covers once that daemon-side support exists. The channel is closed when
+// ctx is canceled or the connection drops; callers should drain it until it
+// closes.
+func (c *Client) Subscribe(ctx context.Context, kinds ...EventKind) (<-chan Event, error) {
+	conn, err := net.Dial("unix", c.eventSocket)
+	if err != nil {
+		return nil, fmt.Errorf("pyprclient: connecting to %s: %w", c.eventSocket, err)
+	}
+
+	wanted := make(map[EventKind]bool, len(kinds))
+	for _, k := range kinds {
+		wanted[k] = true
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer conn.Close()
+		go func() {
+			<-ctx.Done()
+			conn.Close()
+		}()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			name, data, ok := parseEventLine(scanner.Text())
+			if !ok {
+				continue
+			}
+			kind := EventKind(name)
+			if len(wanted) > 0 && !wanted[kind] {
+				continue
+			}
+			select {
+			case events <- Event{Kind: kind, Data: data, TS: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// parseEventLine splits a raw "EVENT>>DATA" line into its event name and
+// comma-separated data fields.
+func parseEventLine(line string) (name string, data []string, ok bool) {
+	name, rest, found := strings.Cut(line, ">>")
+	if !found {
+		return "", nil, false
+	}
+	if rest == "" {
+		return name, nil, true
+	}
+	return name, strings.Split(rest, ","), true
+}
@@ -0,0 +1,83 @@
+package pyprclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestClient starts a fake daemon on a Unix socket that runs handle for
+// every connection it accepts, and returns a Client pointed at it.
+func newTestClient(t *testing.T, handle func(net.Conn)) *Client {
+	t.Helper()
+	sockPath := filepath.Join(t.TempDir(), "test.sock")
+	ln, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return &Client{socketPath: sockPath, timeout: time.Second}
+}
+
+func TestCommandsParsesRegistry(t *testing.T) {
+	client := newTestClient(t, func(conn net.Conn) {
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+		reply, _ := json.Marshal([]CommandInfo{
+			{Name: "toggle", Plugin: "scratchpads", Arity: 1, Doc: "toggle a scratchpad"},
+		})
+		conn.Write(reply)
+	})
+
+	cmds, err := client.Commands(context.Background())
+	if err != nil {
+		t.Fatalf("Commands(): %v", err)
+	}
+	if len(cmds) != 1 || cmds[0].Name != "toggle" || cmds[0].Plugin != "scratchpads" {
+		t.Fatalf("Commands() = %+v", cmds)
+	}
+}
+
+func TestCommandsRejectsUnrecognizedShape(t *testing.T) {
+	client := newTestClient(t, func(conn net.Conn) {
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+		// A daemon that doesn't understand "--commands" and just replies
+		// with its regular config dump instead of a registry array.
+		conn.Write([]byte(`{"general": {}}`))
+	})
+
+	_, err := client.Commands(context.Background())
+	if !errors.Is(err, ErrCommandRegistryUnsupported) {
+		t.Fatalf("Commands() err = %v, want ErrCommandRegistryUnsupported", err)
+	}
+}
+
+func TestCommandsRejectsEmptyNameEntries(t *testing.T) {
+	client := newTestClient(t, func(conn net.Conn) {
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+		// An array shape that happens to unmarshal, but with entries that
+		// aren't really commands.
+		conn.Write([]byte(`[{"plugin":"scratchpads"}]`))
+	})
+
+	_, err := client.Commands(context.Background())
+	if !errors.Is(err, ErrCommandRegistryUnsupported) {
+		t.Fatalf("Commands() err = %v, want ErrCommandRegistryUnsupported", err)
+	}
+}
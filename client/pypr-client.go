@@ -1,12 +1,37 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyprland-community/pyprland/client/pkg/pyprclient"
 )
 
+// envelope is the shape printed when --json is passed, so shell scripts and
+// bar widgets can parse results instead of screen-scraping stdout.
+type envelope struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	OK      bool     `json:"ok"`
+	Stdout  string   `json:"stdout"`
+	Stderr  string   `json:"stderr"`
+}
+
+// event is one line off Hyprland's event socket, normalized into JSON for
+// consumers that would rather not parse "EVENT>>DATA" text.
+type event struct {
+	Event string   `json:"event"`
+	Data  []string `json:"data"`
+	TS    int64    `json:"ts"`
+}
 
 func main() {
 	// If no argument passed, just exit
@@ -14,14 +39,40 @@ func main() {
 		fmt.Fprintf(os.Stderr, "No command passed!\n")
 		os.Exit(0);
 	}
+	// if the command is subscribe, stream Hyprland events instead of talking
+	// to .pyprland.sock
+	if os.Args[1] == "subscribe" {
+		subscribe(os.Args[2:])
+		return
+	}
+	// if the command is batch, read newline-delimited commands from stdin and
+	// issue them as a single pipelined request
+	if os.Args[1] == "batch" {
+		batch(os.Args[2:])
+		return
+	}
+	// if the command is completion, generate a shell-completion script from
+	// the daemon's live command registry, fetched over .pyprland.sock
+	if os.Args[1] == "completion" {
+		completion(os.Args[2:])
+		return
+	}
 	// if the argument is help, print the help text
 	if os.Args[1] == "help" {
 
 		helpText := `
-Syntax: pypr-client [command]
+Syntax: pypr-client [--timeout seconds] [--json] [command]
+
+Flags:
+--timeout seconds    How long to wait for the daemon's reply (default: 5).
+--json               Wrap the request and reply in a JSON envelope.
 
 Available commands:
+subscribe [events...]  Stream Hyprland compositor events from socket2, optionally filtered by name. (pyprland plugin events not yet multiplexed, see pyprclient.SubscribePlugin)
+batch -              Read newline-delimited commands from stdin, issue them as one pipelined request.
+completion {bash|zsh|fish}  Emit a shell-completion script for command names, built from the daemon's live command registry.
 dumpjson             Dump the configuration in JSON format.
+dumpjson --commands  Dump the live command registry (name, owning plugin, arity, doc) as JSON.
 edit                 Edit the configuration file.
 exit                 Exit the daemon.
 help                 Show this help.
@@ -50,32 +101,353 @@ wall                 <next|clear> skip the current background image or stop disp
 		os.Exit(0)
 	}
 
-	// Get the socket path from environment variables
-	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
-	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
-	if runtimeDir == "" || signature == "" {
-		fmt.Fprintf(os.Stderr, "Error: XDG_RUNTIME_DIR or HYPRLAND_INSTANCE_SIGNATURE environment variable not set\n")
+	jsonOutput, timeout, command := parseArgs(os.Args[1:])
+	if len(command) == 0 {
+		fmt.Fprintf(os.Stderr, "No command passed!\n")
+		os.Exit(1)
+	}
+
+	client, err := pyprclient.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	client = client.WithTimeout(timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	reply, err := client.Command(ctx, command[0], command[1:]...)
+	ok := err == nil
+	stderr := ""
+	if err != nil {
+		stderr = err.Error()
+	}
+
+	printResult(jsonOutput, command, ok, reply, stderr)
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// parseArgs pulls --timeout and --json out of args, wherever they appear,
+// and returns what's left as the command (and its arguments) to send.
+func parseArgs(args []string) (jsonOutput bool, timeout time.Duration, command []string) {
+	timeout = pyprclient.DefaultTimeout
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--timeout":
+			i++
+			if i >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: --timeout requires a value\n")
+				os.Exit(1)
+			}
+			timeout = parseTimeout(args[i])
+		case strings.HasPrefix(arg, "--timeout="):
+			timeout = parseTimeout(strings.TrimPrefix(arg, "--timeout="))
+		default:
+			command = append(command, arg)
+		}
+	}
+	return jsonOutput, timeout, command
+}
+
+func parseTimeout(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --timeout value %q\n", value)
+		os.Exit(1)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// subscribe connects to Hyprland's own event socket (.socket2.sock) and
+// streams "EVENT>>DATA" lines, optionally filtered to the requested event
+// names and optionally normalized to JSON. It only sees Hyprland compositor
+// events; pyprland's own plugin events (scratchpad shown/hidden, wallpaper
+// changed, ...) aren't multiplexed here yet - that needs a matching
+// daemon-side "subscribe" command over .pyprland.sock, tracked as a
+// follow-up (see pyprclient.SubscribePlugin).
+func subscribe(args []string) {
+	jsonOutput := false
+	var wanted []pyprclient.EventKind
+	for _, arg := range args {
+		if arg == "--json" {
+			jsonOutput = true
+			continue
+		}
+		wanted = append(wanted, pyprclient.EventKind(arg))
+	}
+
+	client, err := pyprclient.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	events, err := client.Subscribe(context.Background(), wanted...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for evt := range events {
+		if jsonOutput {
+			out := event{Event: string(evt.Kind), Data: evt.Data, TS: evt.TS.Unix()}
+			encoded, err := json.Marshal(out)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON event: %v\n", err)
+				continue
+			}
+			fmt.Println(string(encoded))
+		} else {
+			fmt.Printf("%s>>%s\n", evt.Kind, strings.Join(evt.Data, ","))
+		}
+	}
+}
+
+// batch reads newline-delimited commands from stdin and issues them as a
+// single pipelined request - useful for startup scripts that currently
+// exec-once a dozen pypr calls serially.
+func batch(args []string) {
+	jsonOutput, timeout, rest := parseArgs(args)
+	if len(rest) == 0 || rest[0] != "-" {
+		fmt.Fprintf(os.Stderr, "Usage: pypr-client batch -\n")
+		os.Exit(1)
+	}
+
+	var reqs []pyprclient.Request
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields, err := splitBatchLine(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		reqs = append(reqs, pyprclient.Request{Cmd: fields[0], Args: fields[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	if len(reqs) == 0 {
+		return
+	}
+
+	client, err := pyprclient.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	client = client.WithTimeout(timeout)
 
-	// Construct the socket path
-	socketPath := fmt.Sprintf("%s/hypr/%s/.pyprland.sock", runtimeDir, signature)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	// Connect to the Unix socket
-	conn, err := net.Dial("unix", socketPath)
+	resps, err := client.Batch(ctx, reqs)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error connecting to socket %s: %v\n", socketPath, err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	defer conn.Close()
 
-	// Concatenate all command-line arguments with spaces
-	message := strings.Join(os.Args[1:], " ")
+	failed := false
+	for i, resp := range resps {
+		stderr := ""
+		if !resp.OK {
+			failed = true
+			if resp.Error != nil {
+				stderr = *resp.Error
+			}
+		}
+		printResult(jsonOutput, append([]string{reqs[i].Cmd}, reqs[i].Args...), resp.OK, resp.ResultString(), stderr)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// splitBatchLine tokenizes one batch line into a command and its args,
+// honoring single and double quotes (with backslash escapes inside them)
+// so a scratchpad name, wallpaper path, or menu label containing a space
+// survives as one argument instead of being split apart - the bug the
+// framed protocol exists to fix.
+func splitBatchLine(line string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	inField := false
+	var quote rune
 
-	// Send the message to the socket
-	_, err = conn.Write([]byte(message))
+	data := []byte(line)
+	for i := 0; i < len(data); {
+		ch, size := utf8.DecodeRune(data[i:])
+		switch {
+		case quote != 0:
+			switch {
+			case ch == '\\' && i+size < len(data):
+				next, nsize := utf8.DecodeRune(data[i+size:])
+				if next == quote || next == '\\' {
+					field.WriteRune(next)
+					i += size + nsize
+					continue
+				}
+				field.WriteRune(ch)
+			case ch == quote:
+				quote = 0
+			default:
+				field.WriteRune(ch)
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+			inField = true
+		case ch == ' ' || ch == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(ch)
+			inField = true
+		}
+		i += size
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %q quote in batch line: %s", string(quote), line)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+// completion asks the daemon for its live command registry and emits a
+// shell-completion script for shell, built from it - so enabling a plugin
+// or reloading the config updates completion of command names without
+// editing this binary. It only completes top-level command names; it does
+// not complete argument values (scratchpad names, wallpaper actions, menu
+// entries), which would need the daemon to expose live plugin state beyond
+// the command registry.
+func completion(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: pypr-client completion {bash|zsh|fish}\n")
+		os.Exit(1)
+	}
+	shell := args[0]
+
+	client, err := pyprclient.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pyprclient.DefaultTimeout)
+	defer cancel()
+
+	cmds, err := client.Commands(ctx)
+	if errors.Is(err, pyprclient.ErrCommandRegistryUnsupported) {
+		fmt.Fprintf(os.Stderr, "Error: %v (needs a newer pyprland daemon)\n", err)
+		os.Exit(1)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing to socket: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+
+	switch shell {
+	case "bash":
+		fmt.Print(bashCompletion(cmds))
+	case "zsh":
+		fmt.Print(zshCompletion(cmds))
+	case "fish":
+		fmt.Print(fishCompletion(cmds))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown shell %q, expected bash, zsh or fish\n", shell)
+		os.Exit(1)
+	}
+}
+
+// staticCommands lists the subcommands pypr-client implements itself, which
+// the daemon's registry doesn't know about.
+func staticCommands() []string {
+	return []string{"help", "subscribe", "batch", "completion"}
+}
+
+// describe renders a CommandInfo's one-line doc, annotated with its owning
+// plugin and how many arguments it takes. Argument values themselves
+// (scratchpad names, wallpaper actions, ...) aren't completed - only this
+// hint that they're expected.
+func describe(c pyprclient.CommandInfo) string {
+	doc := c.Doc
+	if c.Arity > 0 {
+		doc = fmt.Sprintf("%s (%d arg(s))", doc, c.Arity)
+	}
+	if c.Plugin == "" {
+		return doc
+	}
+	return fmt.Sprintf("%s [%s]", doc, c.Plugin)
+}
+
+func bashCompletion(cmds []pyprclient.CommandInfo) string {
+	names := staticCommands()
+	for _, c := range cmds {
+		names = append(names, c.Name)
+	}
+	return fmt.Sprintf("complete -W %q pypr-client\n", strings.Join(names, " "))
+}
+
+func zshCompletion(cmds []pyprclient.CommandInfo) string {
+	var b strings.Builder
+	b.WriteString("#compdef pypr-client\n\n_pypr_client() {\n  local -a commands\n  commands=(\n")
+	for _, name := range staticCommands() {
+		fmt.Fprintf(&b, "    %q\n", name)
+	}
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "    %q\n", fmt.Sprintf("%s:%s", c.Name, describe(c)))
+	}
+	b.WriteString("  )\n  _describe 'command' commands\n}\n\n_pypr_client \"$@\"\n")
+	return b.String()
+}
+
+func fishCompletion(cmds []pyprclient.CommandInfo) string {
+	var b strings.Builder
+	for _, name := range staticCommands() {
+		fmt.Fprintf(&b, "complete -c pypr-client -f -a %q\n", name)
+	}
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "complete -c pypr-client -f -a %q -d %q\n", c.Name, describe(c))
+	}
+	return b.String()
+}
+
+func printResult(jsonOutput bool, command []string, ok bool, stdout string, stderr string) {
+	if jsonOutput {
+		env := envelope{
+			Command: command[0],
+			Args:    command[1:],
+			OK:      ok,
+			Stdout:  stdout,
+			Stderr:  stderr,
+		}
+		data, err := json.Marshal(env)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON reply: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	fmt.Print(stdout)
+	if stderr != "" {
+		fmt.Fprintln(os.Stderr, stderr)
+	}
 }
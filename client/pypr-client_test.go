@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestSplitBatchLine(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"simple", "show term", []string{"show", "term"}},
+		{"unquoted spaces split", "wall /path/with space.jpg", []string{"wall", "/path/with", "space.jpg"}},
+		{"double-quoted arg keeps space", `show "my term"`, []string{"show", "my term"}},
+		{"single-quoted arg keeps space", `wall '/path/with space.jpg'`, []string{"wall", "/path/with space.jpg"}},
+		{"escaped quote inside quotes", `menu "say \"hi\""`, []string{"menu", `say "hi"`}},
+		{"unicode passes through unquoted", "show café", []string{"show", "café"}},
+		{"unicode passes through quoted", `show "café terrasse"`, []string{"show", "café terrasse"}},
+		{"empty line", "", nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := splitBatchLine(tc.line)
+			if err != nil {
+				t.Fatalf("splitBatchLine(%q): %v", tc.line, err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("splitBatchLine(%q) = %q, want %q", tc.line, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("splitBatchLine(%q)[%d] = %q, want %q", tc.line, i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitBatchLineUnterminatedQuote(t *testing.T) {
+	if _, err := splitBatchLine(`show "oops`); err == nil {
+		t.Fatal("splitBatchLine: expected an error for an unterminated quote")
+	}
+}